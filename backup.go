@@ -5,6 +5,9 @@ import (
 	"encoding/base32"
 	"fmt"
 	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 // GenerateBackupCodes generates a set of backup codes for recovery purposes
@@ -35,14 +38,9 @@ func GenerateBackupCodes(count int) ([]string, error) {
 
 // ValidateBackupCode validates a backup code (simple constant-time comparison)
 func ValidateBackupCode(providedCode, storedCode string) bool {
-	// Normalize codes (remove spaces, convert to uppercase)
-	provided := strings.ToUpper(strings.ReplaceAll(providedCode, " ", ""))
-	provided = strings.ReplaceAll(provided, "-", "")
-
-	stored := strings.ToUpper(strings.ReplaceAll(storedCode, " ", ""))
-	stored = strings.ReplaceAll(stored, "-", "")
+	provided := normalizeBackupCode(providedCode)
+	stored := normalizeBackupCode(storedCode)
 
-	// Constant-time comparison to prevent timing attacks
 	if len(provided) != len(stored) {
 		return false
 	}
@@ -54,3 +52,98 @@ func ValidateBackupCode(providedCode, storedCode string) bool {
 
 	return result == 0
 }
+
+// normalizeBackupCode strips the spaces and dashes a user might type
+// around a backup code and uppercases what remains, so codes compare
+// equal regardless of how they were formatted for display.
+func normalizeBackupCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.ReplaceAll(code, " ", "")
+	code = strings.ReplaceAll(code, "-", "")
+	return code
+}
+
+// BackupCode is one hashed, single-use backup code within a
+// BackupCodeSet. Hash is a bcrypt hash (which embeds its own salt and
+// cost), so it is safe to persist and does not reveal the plaintext
+// code; a fast general-purpose hash would let a stolen set be
+// brute-forced offline in seconds given how little entropy one backup
+// code carries.
+type BackupCode struct {
+	Hash []byte `json:"hash"`
+	Used bool   `json:"used"`
+}
+
+// BackupCodeSet holds bcrypt hashes of a set of backup codes rather than
+// the plaintext, so it can be stored at rest. Use GenerateBackupCodeSet
+// to create one along with the plaintext codes to show the user once.
+type BackupCodeSet struct {
+	Codes []BackupCode `json:"codes"`
+
+	mu sync.Mutex
+}
+
+// GenerateBackupCodeSet generates count backup codes and returns both the
+// plaintext codes, to display to the user exactly once, and a
+// BackupCodeSet holding only their bcrypt hashes, safe to persist.
+func GenerateBackupCodeSet(count int) ([]string, *BackupCodeSet, error) {
+	plaintext, err := GenerateBackupCodes(count)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	set := &BackupCodeSet{Codes: make([]BackupCode, len(plaintext))}
+	for i, code := range plaintext {
+		hash, err := bcrypt.GenerateFromPassword([]byte(normalizeBackupCode(code)), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to hash backup code: %w", err)
+		}
+
+		set.Codes[i] = BackupCode{Hash: hash}
+	}
+
+	return plaintext, set, nil
+}
+
+// Consume checks providedCode against every unused hash in the set,
+// comparing against the same number of candidates regardless of where
+// (or whether) it matches. On a match, it marks that code used so it can
+// never be consumed again and returns true; otherwise it returns false.
+// The find-and-mark sequence holds s.mu throughout, so two goroutines
+// consuming the same code can't both see it as unused and both win.
+func (s *BackupCodeSet) Consume(providedCode string) (bool, error) {
+	normalized := []byte(normalizeBackupCode(providedCode))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matched := -1
+	for i := range s.Codes {
+		c := &s.Codes[i]
+		if bcrypt.CompareHashAndPassword(c.Hash, normalized) == nil && !c.Used {
+			matched = i
+		}
+	}
+
+	if matched == -1 {
+		return false, nil
+	}
+
+	s.Codes[matched].Used = true
+	return true, nil
+}
+
+// Regenerate replaces every code in the set with a freshly generated one,
+// mirroring a "regenerate backup codes" account-settings action. It
+// returns the new plaintext codes to show the user once.
+func (s *BackupCodeSet) Regenerate() ([]string, error) {
+	plaintext, fresh, err := GenerateBackupCodeSet(len(s.Codes))
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.Codes = fresh.Codes
+	s.mu.Unlock()
+	return plaintext, nil
+}
@@ -0,0 +1,67 @@
+package otp
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestBackupCodeSetConsume(t *testing.T) {
+	plaintext, set, err := GenerateBackupCodeSet(3)
+	if err != nil {
+		t.Fatalf("GenerateBackupCodeSet: %v", err)
+	}
+
+	ok, err := set.Consume(plaintext[0])
+	if err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	if !ok {
+		t.Fatalf("Consume(%q) = false, want true", plaintext[0])
+	}
+
+	ok, err = set.Consume(plaintext[0])
+	if err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	if ok {
+		t.Fatalf("Consume(%q) a second time = true, want false", plaintext[0])
+	}
+}
+
+// TestBackupCodeSetConsumeConcurrent guards against a check-then-act
+// race in Consume: many goroutines racing to consume the same
+// code must produce exactly one winner, never zero or more than one.
+func TestBackupCodeSetConsumeConcurrent(t *testing.T) {
+	plaintext, set, err := GenerateBackupCodeSet(1)
+	if err != nil {
+		t.Fatalf("GenerateBackupCodeSet: %v", err)
+	}
+
+	const goroutines = 20
+	var wg sync.WaitGroup
+	results := make([]bool, goroutines)
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ok, err := set.Consume(plaintext[0])
+			if err != nil {
+				t.Errorf("Consume: %v", err)
+				return
+			}
+			results[i] = ok
+		}(i)
+	}
+	wg.Wait()
+
+	wins := 0
+	for _, ok := range results {
+		if ok {
+			wins++
+		}
+	}
+	if wins != 1 {
+		t.Fatalf("got %d concurrent winners, want exactly 1", wins)
+	}
+}
@@ -0,0 +1,144 @@
+package otp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrInvalidKeyLength  = errors.New("key must be 32 bytes (AES-256)")
+	ErrInvalidCiphertext = errors.New("ciphertext is too short or malformed")
+	ErrUnknownKeyID      = errors.New("ciphertext references an unknown key ID")
+)
+
+// SecretCipher encrypts and decrypts the plaintext base32 secret before
+// it is written to a Store, so a stolen database backup doesn't hand
+// over usable secrets the way a plaintext column would.
+type SecretCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// AESGCMCipher is the default SecretCipher, encrypting with AES-256-GCM.
+// It supports key rotation: every ciphertext is prefixed with a one-byte
+// key ID, so ciphertexts sealed under a retired key keep decrypting
+// after AddKey introduces a new active one. The zero value is not
+// usable; construct one with NewAESGCMCipher.
+type AESGCMCipher struct {
+	mu        sync.RWMutex
+	keys      map[byte][]byte
+	activeKey byte
+}
+
+// NewAESGCMCipher creates an AESGCMCipher whose only (and therefore
+// active) key has ID 0. key must be 32 bytes.
+func NewAESGCMCipher(key []byte) (*AESGCMCipher, error) {
+	c := &AESGCMCipher{keys: make(map[byte][]byte)}
+	if err := c.AddKey(0, key); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// AddKey registers key under keyID and makes it the active key used by
+// future calls to Encrypt; ciphertexts produced under earlier key IDs
+// keep decrypting with Decrypt. key must be 32 bytes.
+func (c *AESGCMCipher) AddKey(keyID byte, key []byte) error {
+	if len(key) != 32 {
+		return ErrInvalidKeyLength
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.keys[keyID] = append([]byte(nil), key...)
+	c.activeKey = keyID
+	return nil
+}
+
+// Encrypt encrypts plaintext with the active key, returning a one-byte
+// key ID followed by a random nonce and the AES-GCM sealed ciphertext.
+func (c *AESGCMCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	c.mu.RLock()
+	activeKey := c.activeKey
+	c.mu.RUnlock()
+
+	gcm, err := c.gcm(activeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return append([]byte{activeKey}, sealed...), nil
+}
+
+// Decrypt decrypts ciphertext produced by Encrypt, looking up the key by
+// the ID stored in its first byte.
+func (c *AESGCMCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 1 {
+		return nil, ErrInvalidCiphertext
+	}
+
+	keyID, sealed := ciphertext[0], ciphertext[1:]
+	gcm, err := c.gcm(keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, sealed := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return plaintext, nil
+}
+
+func (c *AESGCMCipher) gcm(keyID byte) (cipher.AEAD, error) {
+	c.mu.RLock()
+	key, ok := c.keys[keyID]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptedSecret returns c.Secret encrypted with cipher, for storing in
+// place of the plaintext secret.
+func (c *Config) EncryptedSecret(cipher SecretCipher) ([]byte, error) {
+	return cipher.Encrypt([]byte(c.Secret))
+}
+
+// LoadConfigWithCipher decrypts encrypted with cipher and returns a copy
+// of template with its Secret replaced by the decrypted value; template
+// itself is left unmodified. The decrypted buffer is zeroed before
+// returning, so the returned Config's Secret is the only copy of the
+// plaintext secret this function leaves behind.
+func LoadConfigWithCipher(encrypted []byte, cipher SecretCipher, template *Config) (*Config, error) {
+	secret, err := cipher.Decrypt(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(secret)
+
+	c := *template
+	c.Secret = string(secret)
+	return &c, nil
+}
@@ -0,0 +1,70 @@
+package otp
+
+// Encoding selects the symbol alphabet an OTP is rendered with.
+type Encoding int
+
+const (
+	// EncodingDecimal renders Digits decimal digits (0-9). This is the
+	// zero value, so an unset Config keeps its historical behavior.
+	EncodingDecimal Encoding = iota
+	// EncodingSteam renders a 5-character code from the alphabet Steam's
+	// mobile authenticator uses.
+	EncodingSteam
+	// EncodingCrockfordBase32 renders a code from Crockford's base32
+	// alphabet, for more entropy per character than decimal digits.
+	EncodingCrockfordBase32
+)
+
+// steamAlphabet is the symbol set Steam Guard codes are drawn from.
+const steamAlphabet = "23456789BCDFGHJKMNPQRTVWXY"
+
+// steamCodeLength is the fixed length of a Steam Guard code.
+const steamCodeLength = 5
+
+// crockfordBase32Alphabet is Crockford's base32 alphabet, which drops
+// the visually ambiguous I, L, O, and U.
+const crockfordBase32Alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// crockfordBase32DefaultLength is used when Length is unset for
+// EncodingCrockfordBase32.
+const crockfordBase32DefaultLength = 8
+
+// alphabet returns the symbols and length to render an OTP with,
+// honoring a custom Alphabet/Length over Encoding when Alphabet is set.
+func (c *Config) alphabet() ([]rune, int) {
+	if len(c.Alphabet) > 0 {
+		length := c.Length
+		if length <= 0 {
+			length = len(c.Alphabet)
+		}
+		return c.Alphabet, length
+	}
+
+	switch c.Encoding {
+	case EncodingSteam:
+		return []rune(steamAlphabet), steamCodeLength
+	case EncodingCrockfordBase32:
+		length := c.Length
+		if length <= 0 {
+			length = crockfordBase32DefaultLength
+		}
+		return []rune(crockfordBase32Alphabet), length
+	default:
+		return []rune("0123456789"), c.Digits
+	}
+}
+
+// encodeSymbols expands value into length symbols drawn from alphabet by
+// repeatedly taking value % len(alphabet) and dividing, filling positions
+// from the last to the first, so the least-significant symbol comes last.
+// For the decimal alphabet this reproduces the historical "value mod
+// 10^digits, zero-padded" formatting exactly.
+func encodeSymbols(value uint32, alphabet []rune, length int) string {
+	base := uint32(len(alphabet))
+	symbols := make([]rune, length)
+	for i := length - 1; i >= 0; i-- {
+		symbols[i] = alphabet[value%base]
+		value /= base
+	}
+	return string(symbols)
+}
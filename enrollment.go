@@ -0,0 +1,154 @@
+package otp
+
+import (
+	"image"
+	"time"
+)
+
+// Option configures a pending Enrollment created by BeginEnrollment.
+type Option func(*enrollmentOptions)
+
+type enrollmentOptions struct {
+	digits       int
+	period       int
+	algorithm    Algorithm
+	secretLength int
+	expiry       time.Duration
+	store        Store
+}
+
+func defaultEnrollmentOptions() enrollmentOptions {
+	return enrollmentOptions{
+		digits:       6,
+		period:       30,
+		algorithm:    AlgorithmSHA1,
+		secretLength: 32,
+		expiry:       10 * time.Minute,
+	}
+}
+
+// WithDigits sets the number of digits in the enrolled OTP (default: 6).
+func WithDigits(digits int) Option {
+	return func(o *enrollmentOptions) { o.digits = digits }
+}
+
+// WithPeriod sets the TOTP period in seconds (default: 30).
+func WithPeriod(period int) Option {
+	return func(o *enrollmentOptions) { o.period = period }
+}
+
+// WithAlgorithm sets the hashing algorithm (default: AlgorithmSHA1).
+func WithAlgorithm(algorithm Algorithm) Option {
+	return func(o *enrollmentOptions) { o.algorithm = algorithm }
+}
+
+// WithSecretLength sets the number of random bytes used to generate the
+// secret (default: 32).
+func WithSecretLength(length int) Option {
+	return func(o *enrollmentOptions) { o.secretLength = length }
+}
+
+// WithExpiry sets how long the enrollment accepts confirmations before
+// Confirm starts returning ErrEnrollmentExpired (default: 10 minutes).
+func WithExpiry(d time.Duration) Option {
+	return func(o *enrollmentOptions) { o.expiry = d }
+}
+
+// WithStore saves the activated Config to store, keyed by accountName,
+// once Confirm succeeds.
+func WithStore(store Store) Option {
+	return func(o *enrollmentOptions) { o.store = store }
+}
+
+// Enrollment holds a freshly generated secret that has not been
+// activated yet. Real 2FA enrollment never trusts a secret until the
+// user proves possession of it by entering a valid code from their
+// authenticator app; call Confirm with that code to activate it.
+type Enrollment struct {
+	config    *Config
+	subject   string
+	store     Store
+	createdAt time.Time
+	expiry    time.Duration
+}
+
+// BeginEnrollment generates a new secret for issuer/accountName and
+// returns a pending Enrollment. The enrollment's Config is not yet
+// activated or persisted; call Confirm with a code from the user's
+// authenticator app to do either.
+func BeginEnrollment(issuer, accountName string, opts ...Option) (*Enrollment, error) {
+	o := defaultEnrollmentOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	secret, err := GenerateSecret(o.secretLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Enrollment{
+		config: &Config{
+			Secret:      secret,
+			Digits:      o.digits,
+			Algorithm:   o.algorithm,
+			Period:      o.period,
+			Issuer:      issuer,
+			AccountName: accountName,
+		},
+		subject:   accountName,
+		store:     o.store,
+		createdAt: time.Now(),
+		expiry:    o.expiry,
+	}, nil
+}
+
+// OTPAuthURL returns the otpauth:// URL for the pending secret, to
+// render into a QR code for the user to scan.
+func (e *Enrollment) OTPAuthURL() (string, error) {
+	return e.config.OTPAuthURL()
+}
+
+// QRCode renders a QR code for the pending secret's otpauth URL.
+func (e *Enrollment) QRCode(size int) (image.Image, error) {
+	return e.config.QRCode(size)
+}
+
+// QRCodePNG renders a QR code for the pending secret's otpauth URL as a
+// PNG image.
+func (e *Enrollment) QRCodePNG(size int) ([]byte, error) {
+	return e.config.QRCodePNG(size)
+}
+
+// Expiry returns when this pending enrollment stops accepting
+// confirmations.
+func (e *Enrollment) Expiry() time.Time {
+	return e.createdAt.Add(e.expiry)
+}
+
+// Confirm validates code against the pending secret with a small time
+// window and only on success returns the now-activated Config. A stale
+// enrollment (past Expiry) is rejected even if code would otherwise be
+// valid. If a Store was supplied via WithStore, the activated Config is
+// saved under accountName before it is returned.
+func (e *Enrollment) Confirm(code string) (*Config, error) {
+	if time.Now().After(e.Expiry()) {
+		return nil, ErrEnrollmentExpired
+	}
+
+	valid, err := e.config.ValidateTOTP(code, 1)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrInvalidCode
+	}
+
+	if e.store != nil {
+		if err := e.store.Save(e.subject, e.config); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.config, nil
+}
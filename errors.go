@@ -10,4 +10,8 @@ var (
 	ErrInvalidAlgorithm   = errors.New("invalid algorithm")
 	ErrInvalidPeriod      = errors.New("period must be greater than 0")
 	ErrInvalidCounter     = errors.New("counter must be non-negative")
+	ErrSubjectNotFound    = errors.New("no stored configuration for subject")
+	ErrCodeAlreadyUsed    = errors.New("code has already been used")
+	ErrEnrollmentExpired  = errors.New("enrollment has expired")
+	ErrInvalidCode        = errors.New("code is invalid")
 )
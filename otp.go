@@ -10,7 +10,6 @@ import (
 	"encoding/binary"
 	"fmt"
 	"hash"
-	"math"
 )
 
 // Algorithm represents the hashing algorithm used for OTP generation
@@ -52,6 +51,17 @@ type Config struct {
 	Issuer string
 	// AccountName is the name of the account
 	AccountName string
+	// Encoding selects the symbol alphabet OTPs are rendered with
+	// (default: EncodingDecimal, using Digits decimal digits). Ignored
+	// if Alphabet is set.
+	Encoding Encoding
+	// Alphabet, if non-empty, overrides Encoding with a custom symbol
+	// set; Length (or len(Alphabet) if Length is zero) controls how many
+	// symbols are drawn from it.
+	Alphabet []rune
+	// Length is the number of symbols to render when Alphabet is set or
+	// Encoding is EncodingCrockfordBase32; it is ignored otherwise.
+	Length int
 }
 
 // DefaultConfig returns a default configuration
@@ -89,7 +99,8 @@ func (c *Config) getHash() func() hash.Hash {
 
 // generateOTP generates an OTP for the given counter value
 func (c *Config) generateOTP(counter uint64) (string, error) {
-	if c.Digits <= 0 || c.Digits > 10 {
+	alphabet, length := c.alphabet()
+	if length <= 0 || length > 10 {
 		return "", ErrInvalidDigits
 	}
 
@@ -97,6 +108,7 @@ func (c *Config) generateOTP(counter uint64) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to decode secret: %w", err)
 	}
+	defer zeroBytes(secretBytes)
 
 	// Convert counter to byte array
 	counterBytes := make([]byte, 8)
@@ -111,10 +123,5 @@ func (c *Config) generateOTP(counter uint64) (string, error) {
 	offset := hash[len(hash)-1] & 0x0f
 	truncatedHash := binary.BigEndian.Uint32(hash[offset:offset+4]) & 0x7fffffff
 
-	// Generate OTP
-	otp := truncatedHash % uint32(math.Pow10(c.Digits))
-
-	// Format with leading zeros
-	format := fmt.Sprintf("%%0%dd", c.Digits)
-	return fmt.Sprintf(format, otp), nil
+	return encodeSymbols(truncatedHash, alphabet, length), nil
 }
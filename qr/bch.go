@@ -0,0 +1,36 @@
+package qr
+
+// bchEncode appends the BCH error-correction bits a QR format/version
+// info field requires by dividing data (shifted up by the generator's
+// degree) by generator and appending the remainder.
+func bchEncode(data uint32, generator uint32) uint32 {
+	ecBits := bitLength(generator) - 1
+	remainder := data << uint(ecBits)
+	for bitLength(remainder) >= bitLength(generator) {
+		remainder ^= generator << uint(bitLength(remainder)-bitLength(generator))
+	}
+	return (data << uint(ecBits)) | remainder
+}
+
+func bitLength(v uint32) int {
+	n := 0
+	for v > 0 {
+		v >>= 1
+		n++
+	}
+	return n
+}
+
+// formatBits computes the 15-bit format info field for error-correction
+// level M with the given mask pattern index.
+func formatBits(maskIndex int) uint32 {
+	const levelM = 0b00
+	data := uint32(levelM<<3) | uint32(maskIndex)
+	return bchEncode(data, 0x537) ^ 0x5412
+}
+
+// versionInfoBits computes the 18-bit version info field used for
+// version 7 and above.
+func versionInfoBits(version int) uint32 {
+	return bchEncode(uint32(version), 0x1F25)
+}
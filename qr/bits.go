@@ -0,0 +1,50 @@
+package qr
+
+// bitWriter accumulates a stream of bits, MSB first, as used throughout
+// the QR bit stream (mode indicator, character count, data, padding).
+type bitWriter struct {
+	bits []bool
+}
+
+func (w *bitWriter) writeBits(value uint32, count int) {
+	for i := count - 1; i >= 0; i-- {
+		w.bits = append(w.bits, (value>>uint(i))&1 == 1)
+	}
+}
+
+func (w *bitWriter) len() int { return len(w.bits) }
+
+func (w *bitWriter) padToByte() {
+	for len(w.bits)%8 != 0 {
+		w.bits = append(w.bits, false)
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	out := make([]byte, len(w.bits)/8)
+	for i := range out {
+		var b byte
+		for j := 0; j < 8; j++ {
+			b <<= 1
+			if w.bits[i*8+j] {
+				b |= 1
+			}
+		}
+		out[i] = b
+	}
+	return out
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
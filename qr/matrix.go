@@ -0,0 +1,284 @@
+package qr
+
+// matrix is the module grid being built up for one QR symbol. reserved
+// tracks which modules belong to function patterns (finder, timing,
+// alignment, format/version info) so data placement and masking can skip
+// them.
+type matrix struct {
+	size     int
+	modules  []bool
+	reserved []bool
+}
+
+func newMatrix(size int) *matrix {
+	return &matrix{
+		size:     size,
+		modules:  make([]bool, size*size),
+		reserved: make([]bool, size*size),
+	}
+}
+
+func (m *matrix) set(x, y int, v bool) {
+	if x < 0 || y < 0 || x >= m.size || y >= m.size {
+		return
+	}
+	m.modules[y*m.size+x] = v
+	m.reserved[y*m.size+x] = true
+}
+
+func (m *matrix) get(x, y int) bool { return m.modules[y*m.size+x] }
+
+func (m *matrix) isReserved(x, y int) bool { return m.reserved[y*m.size+x] }
+
+// placeFinder draws a 7x7 finder pattern plus its white separator with
+// its top-left corner at (x, y).
+func (m *matrix) placeFinder(x, y int) {
+	for dy := -1; dy <= 7; dy++ {
+		for dx := -1; dx <= 7; dx++ {
+			black := false
+			if dx >= 0 && dx <= 6 && dy >= 0 && dy <= 6 {
+				if dx == 0 || dx == 6 || dy == 0 || dy == 6 {
+					black = true
+				} else if dx >= 2 && dx <= 4 && dy >= 2 && dy <= 4 {
+					black = true
+				}
+			}
+			m.set(x+dx, y+dy, black)
+		}
+	}
+}
+
+// placeTiming draws the alternating timing patterns on row 6 and column 6
+// between the finder patterns.
+func (m *matrix) placeTiming() {
+	for i := 8; i < m.size-8; i++ {
+		v := i%2 == 0
+		m.set(i, 6, v)
+		m.set(6, i, v)
+	}
+}
+
+// placeAlignment draws an alignment pattern at every combination of the
+// given center coordinates, except where it would overlap a finder
+// pattern.
+func (m *matrix) placeAlignment(positions []int) {
+	for _, r := range positions {
+		for _, c := range positions {
+			if m.overlapsFinder(c, r) {
+				continue
+			}
+			m.placeAlignmentPattern(c, r)
+		}
+	}
+}
+
+func (m *matrix) overlapsFinder(cx, cy int) bool {
+	inTopLeft := cx <= 8 && cy <= 8
+	inTopRight := cx >= m.size-9 && cy <= 8
+	inBottomLeft := cx <= 8 && cy >= m.size-9
+	return inTopLeft || inTopRight || inBottomLeft
+}
+
+func (m *matrix) placeAlignmentPattern(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			black := dx == -2 || dx == 2 || dy == -2 || dy == 2 || (dx == 0 && dy == 0)
+			m.set(cx+dx, cy+dy, black)
+		}
+	}
+}
+
+// reserveFormatInfo marks the two 15-bit format-info strips around the
+// finder patterns as reserved, so data placement skips them.
+func (m *matrix) reserveFormatInfo() {
+	size := m.size
+
+	for i := 0; i <= 5; i++ {
+		m.set(i, 8, false)
+	}
+	m.set(7, 8, false)
+	m.set(8, 8, false)
+	m.set(8, 7, false)
+	for row := 0; row <= 5; row++ {
+		m.set(8, row, false)
+	}
+
+	for i := 0; i <= 7; i++ {
+		m.set(size-1-i, 8, false)
+	}
+	for row := size - 7; row < size; row++ {
+		m.set(8, row, false)
+	}
+}
+
+// placeFormatInfo writes the 15-bit format info (error-correction level
+// and mask pattern) into both reserved strips.
+func (m *matrix) placeFormatInfo(bits uint32) {
+	get := func(i int) bool { return (bits>>uint(14-i))&1 == 1 }
+	size := m.size
+
+	for i := 0; i <= 5; i++ {
+		m.set(i, 8, get(i))
+	}
+	m.set(7, 8, get(6))
+	m.set(8, 8, get(7))
+	m.set(8, 7, get(8))
+	idx := 9
+	for row := 5; row >= 0; row-- {
+		m.set(8, row, get(idx))
+		idx++
+	}
+
+	// The second copy runs the other way: the least significant bit sits
+	// at the right end of row 8 and the most significant at the bottom of
+	// column 8.
+	for i := 0; i <= 7; i++ {
+		m.set(size-1-i, 8, get(14-i))
+	}
+	for i := 8; i <= 14; i++ {
+		m.set(8, size-15+i, get(14-i))
+	}
+}
+
+// reserveVersionInfo marks the two 18-bit version-info blocks (used for
+// version 7 and above) as reserved.
+func (m *matrix) reserveVersionInfo() {
+	size := m.size
+	for col := 0; col < 6; col++ {
+		for row := size - 11; row < size-8; row++ {
+			m.set(col, row, false)
+			m.set(row, col, false)
+		}
+	}
+}
+
+// placeVersionInfo writes the 18-bit version info into both blocks.
+func (m *matrix) placeVersionInfo(bits uint32) {
+	size := m.size
+	get := func(i int) bool { return (bits>>uint(i))&1 == 1 }
+
+	i := 0
+	for col := 0; col < 6; col++ {
+		for row := size - 11; row < size-8; row++ {
+			v := get(i)
+			m.set(col, row, v)
+			m.set(row, col, v)
+			i++
+		}
+	}
+}
+
+// placeData fills every non-reserved module with bits, walking the grid
+// in the zigzag, bottom-up/top-down two-column pattern the QR standard
+// requires, skipping the vertical timing column.
+func (m *matrix) placeData(bits []bool) {
+	bitIndex := 0
+	size := m.size
+	upward := true
+
+	for col := size - 1; col > 0; col -= 2 {
+		if col == 6 {
+			col--
+		}
+		for i := 0; i < size; i++ {
+			row := i
+			if upward {
+				row = size - 1 - i
+			}
+			for _, c := range [2]int{col, col - 1} {
+				if m.isReserved(c, row) {
+					continue
+				}
+				var v bool
+				if bitIndex < len(bits) {
+					v = bits[bitIndex]
+				}
+				bitIndex++
+				m.modules[row*size+c] = v
+			}
+		}
+		upward = !upward
+	}
+}
+
+var maskFuncs = [8]func(x, y int) bool{
+	func(x, y int) bool { return (x+y)%2 == 0 },
+	func(x, y int) bool { return y%2 == 0 },
+	func(x, y int) bool { return x%3 == 0 },
+	func(x, y int) bool { return (x+y)%3 == 0 },
+	func(x, y int) bool { return (y/2+x/3)%2 == 0 },
+	func(x, y int) bool { return (x*y)%2+(x*y)%3 == 0 },
+	func(x, y int) bool { return ((x*y)%2+(x*y)%3)%2 == 0 },
+	func(x, y int) bool { return ((x+y)%2+(x*y)%3)%2 == 0 },
+}
+
+// applyMask XORs the given mask pattern over every non-reserved module.
+// Masks are involutions, so calling this a second time with the same
+// index undoes it.
+func (m *matrix) applyMask(maskIndex int) {
+	f := maskFuncs[maskIndex]
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if m.isReserved(x, y) {
+				continue
+			}
+			if f(x, y) {
+				idx := y*m.size + x
+				m.modules[idx] = !m.modules[idx]
+			}
+		}
+	}
+}
+
+// penalty scores the current matrix for how hard it would be for a
+// scanner to read: long runs of same-colored modules and an overall dark
+// module imbalance both increase the score. This is a simplified version
+// of the four QR penalty rules (it omits the 2x2 block and
+// finder-lookalike rules) that is enough to steer mask selection away
+// from obviously bad patterns.
+func (m *matrix) penalty() int {
+	size := m.size
+	score := 0
+
+	scoreRuns := func(get func(i int) bool) int {
+		s := 0
+		run := 1
+		last := get(0)
+		for i := 1; i < size; i++ {
+			v := get(i)
+			if v == last {
+				run++
+				continue
+			}
+			if run >= 5 {
+				s += 3 + (run - 5)
+			}
+			run = 1
+			last = v
+		}
+		if run >= 5 {
+			s += 3 + (run - 5)
+		}
+		return s
+	}
+
+	for y := 0; y < size; y++ {
+		score += scoreRuns(func(i int) bool { return m.get(i, y) })
+	}
+	for x := 0; x < size; x++ {
+		score += scoreRuns(func(i int) bool { return m.get(x, i) })
+	}
+
+	dark := 0
+	for _, v := range m.modules {
+		if v {
+			dark++
+		}
+	}
+	percent := dark * 100 / (size * size)
+	prev5 := percent / 5 * 5
+	next5 := prev5 + 5
+	score += min(abs(prev5-50), abs(next5-50)) / 5 * 10
+
+	return score
+}
@@ -0,0 +1,224 @@
+// Package qr is a small, dependency-free QR code encoder. It exists so
+// that otpauth:// URLs (and the secrets embedded in them) can be turned
+// into a scannable code entirely in-process, without handing them to a
+// third-party QR rendering service.
+package qr
+
+import "errors"
+
+// Code is an encoded QR code symbol: a square matrix of modules, each
+// either black (true) or white (false). It does not include the quiet
+// zone border that scanners expect around the symbol.
+type Code struct {
+	// Size is the number of modules per side.
+	Size int
+	// Bitmap holds Size*Size modules in row-major order.
+	Bitmap []bool
+}
+
+// At reports whether the module at (x, y) is black.
+func (c *Code) At(x, y int) bool {
+	return c.Bitmap[y*c.Size+x]
+}
+
+// ErrDataTooLarge is returned by Encode when data does not fit in the
+// largest supported QR version at error-correction level M.
+var ErrDataTooLarge = errors.New("qr: data too large for supported versions (max 213 bytes at level M)")
+
+// versionInfo describes the codeword layout of one QR version at
+// error-correction level M.
+type versionInfo struct {
+	totalCodewords int
+	ecPerBlock     int
+	group1Blocks   int
+	group1Size     int
+	group2Blocks   int
+	group2Size     int
+	alignment      []int
+	remainderBits  int
+}
+
+func (vi versionInfo) dataCodewords() int {
+	return vi.group1Blocks*vi.group1Size + vi.group2Blocks*vi.group2Size
+}
+
+// versions holds the level-M codeword layout for versions 1 through 10,
+// the range this package supports. Version 10 has 216 data codewords,
+// which after the byte-mode header hold up to 213 bytes: that comfortably
+// fits an otpauth:// URL for all but pathologically long issuer or
+// account names.
+var versions = [10]versionInfo{
+	{26, 10, 1, 16, 0, 0, nil, 0},
+	{44, 16, 1, 28, 0, 0, []int{6, 18}, 7},
+	{70, 26, 1, 44, 0, 0, []int{6, 22}, 7},
+	{100, 18, 2, 32, 0, 0, []int{6, 26}, 7},
+	{134, 24, 2, 43, 0, 0, []int{6, 30}, 7},
+	{172, 16, 4, 27, 0, 0, []int{6, 34}, 7},
+	{196, 18, 4, 31, 0, 0, []int{6, 22, 38}, 0},
+	{242, 22, 2, 38, 2, 39, []int{6, 24, 42}, 0},
+	{292, 22, 3, 36, 2, 37, []int{6, 26, 46}, 0},
+	{346, 26, 4, 43, 1, 44, []int{6, 28, 50}, 0},
+}
+
+// Encode encodes data in QR byte mode at error-correction level M,
+// choosing the smallest supported version (1-10) that fits, and returns
+// the resulting symbol.
+func Encode(data []byte) (*Code, error) {
+	version, vi, charCountBits := chooseVersion(data)
+	if version == 0 {
+		return nil, ErrDataTooLarge
+	}
+
+	codewords := buildCodewords(data, vi, charCountBits)
+	dataBlocks, ecBlocks := splitAndCorrect(codewords, vi)
+	bits := interleave(dataBlocks, ecBlocks, vi.remainderBits)
+
+	return render(version, vi, bits), nil
+}
+
+func chooseVersion(data []byte) (version int, vi versionInfo, charCountBits int) {
+	for v := 1; v <= len(versions); v++ {
+		candidate := versions[v-1]
+		bits := 8
+		if v >= 10 {
+			bits = 16
+		}
+		headerBits := 4 + bits
+		capacity := candidate.dataCodewords() - (headerBits+7)/8
+		if len(data) <= capacity {
+			return v, candidate, bits
+		}
+	}
+	return 0, versionInfo{}, 0
+}
+
+// buildCodewords assembles the byte-mode bit stream (mode indicator,
+// character count, data, terminator, bit padding) and pads it out to the
+// version's full data codeword count with the standard 0xEC/0x11 filler
+// bytes.
+func buildCodewords(data []byte, vi versionInfo, charCountBits int) []byte {
+	w := &bitWriter{}
+	w.writeBits(0b0100, 4) // byte mode
+	w.writeBits(uint32(len(data)), charCountBits)
+	for _, b := range data {
+		w.writeBits(uint32(b), 8)
+	}
+
+	capacityBits := vi.dataCodewords() * 8
+	if remaining := capacityBits - w.len(); remaining > 0 {
+		w.writeBits(0, min(4, remaining))
+	}
+	w.padToByte()
+
+	pad := [2]byte{0xEC, 0x11}
+	for i := 0; w.len() < capacityBits; i++ {
+		w.writeBits(uint32(pad[i%2]), 8)
+	}
+
+	return w.bytes()
+}
+
+// splitAndCorrect splits codewords into the blocks vi's group layout
+// describes and computes each block's Reed-Solomon error-correction
+// codewords.
+func splitAndCorrect(codewords []byte, vi versionInfo) (dataBlocks, ecBlocks [][]byte) {
+	offset := 0
+	addGroup := func(count, size int) {
+		for i := 0; i < count; i++ {
+			block := codewords[offset : offset+size]
+			offset += size
+			dataBlocks = append(dataBlocks, block)
+			ecBlocks = append(ecBlocks, reedSolomonEncode(block, vi.ecPerBlock))
+		}
+	}
+	addGroup(vi.group1Blocks, vi.group1Size)
+	addGroup(vi.group2Blocks, vi.group2Size)
+	return dataBlocks, ecBlocks
+}
+
+// interleave combines the per-block data and EC codewords in the
+// column-wise order the QR standard requires, then expands the result
+// into individual bits with the version's trailing remainder bits.
+func interleave(dataBlocks, ecBlocks [][]byte, remainderBits int) []bool {
+	var codewords []byte
+
+	maxLen := func(blocks [][]byte) int {
+		n := 0
+		for _, b := range blocks {
+			n = max(n, len(b))
+		}
+		return n
+	}
+
+	for i, n := 0, maxLen(dataBlocks); i < n; i++ {
+		for _, b := range dataBlocks {
+			if i < len(b) {
+				codewords = append(codewords, b[i])
+			}
+		}
+	}
+	for i, n := 0, maxLen(ecBlocks); i < n; i++ {
+		for _, b := range ecBlocks {
+			if i < len(b) {
+				codewords = append(codewords, b[i])
+			}
+		}
+	}
+
+	bits := make([]bool, 0, len(codewords)*8+remainderBits)
+	for _, b := range codewords {
+		for i := 7; i >= 0; i-- {
+			bits = append(bits, (b>>uint(i))&1 == 1)
+		}
+	}
+	for i := 0; i < remainderBits; i++ {
+		bits = append(bits, false)
+	}
+	return bits
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// render lays out the function patterns, places the interleaved data
+// bits, picks the mask pattern with the lowest penalty score, and writes
+// the final format and version info.
+func render(version int, vi versionInfo, dataBits []bool) *Code {
+	size := 17 + 4*version
+	m := newMatrix(size)
+
+	m.placeFinder(0, 0)
+	m.placeFinder(size-7, 0)
+	m.placeFinder(0, size-7)
+	m.placeTiming()
+	m.placeAlignment(vi.alignment)
+	m.set(8, 4*version+9, true) // dark module
+
+	m.reserveFormatInfo()
+	if version >= 7 {
+		m.reserveVersionInfo()
+	}
+
+	m.placeData(dataBits)
+
+	bestMask, bestScore := 0, -1
+	for i := 0; i < 8; i++ {
+		m.applyMask(i)
+		if score := m.penalty(); bestScore == -1 || score < bestScore {
+			bestScore, bestMask = score, i
+		}
+		m.applyMask(i) // masks are involutions: undo before trying the next
+	}
+	m.applyMask(bestMask)
+
+	m.placeFormatInfo(formatBits(bestMask))
+	if version >= 7 {
+		m.placeVersionInfo(versionInfoBits(version))
+	}
+
+	return &Code{Size: size, Bitmap: m.modules}
+}
@@ -0,0 +1,318 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// This file decodes a *Code independently of render: it re-derives the
+// mask from the format info bits, walks the same zigzag order Encode
+// uses to pull bits back out, deinterleaves codewords per the version
+// layout, and checks each block's Reed-Solomon codewords against what
+// reedSolomonEncode produces for the recovered data before parsing the
+// byte-mode stream. A bug in mask application, bit placement, or
+// interleaving should turn into a mismatch here rather than an
+// undetected corrupt symbol. The mask patterns (maskFuncs) and the
+// Reed-Solomon arithmetic (reedSolomonEncode) are the package's own, so
+// this round trip does not check them against the QR standard.
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	cases := []string{
+		"hello",
+		"otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&digits=6&algorithm=SHA1&period=30&issuer=Example",
+		"otpauth://totp/" + strings.Repeat("A", 45) + ":" + strings.Repeat("b", 45) +
+			"?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&digits=6&algorithm=SHA256&period=30",
+	}
+
+	for _, data := range cases {
+		code, err := Encode([]byte(data))
+		if err != nil {
+			t.Fatalf("Encode(%q): %v", data, err)
+		}
+
+		got, err := decodeByteMode(code)
+		if err != nil {
+			t.Fatalf("decodeByteMode for %q: %v", data, err)
+		}
+		if got != data {
+			t.Fatalf("round trip mismatch: got %q, want %q", got, data)
+		}
+	}
+}
+
+func TestEncodeDataTooLarge(t *testing.T) {
+	_, err := Encode(make([]byte, 1000))
+	if err != ErrDataTooLarge {
+		t.Fatalf("Encode(1000 bytes): got err %v, want ErrDataTooLarge", err)
+	}
+}
+
+func decodeByteMode(code *Code) (string, error) {
+	size := code.Size
+	version := (size - 17) / 4
+	if version < 1 || version > len(versions) {
+		return "", fmt.Errorf("unsupported symbol size %d", size)
+	}
+	vi := versions[version-1]
+
+	reserved := functionModules(size, version, vi.alignment)
+
+	maskIndex, err := readMask(code)
+	if err != nil {
+		return "", err
+	}
+
+	bits := extractDataBits(code, reserved, maskIndex)
+	codewords := bitsToBytes(bits)
+
+	dataBlocks, ecBlocks := deinterleave(codewords, vi)
+	for i, block := range dataBlocks {
+		want := reedSolomonEncode(block, vi.ecPerBlock)
+		if !bytes.Equal(want, ecBlocks[i]) {
+			return "", fmt.Errorf("block %d: recomputed EC codewords do not match encoded ones", i)
+		}
+	}
+
+	return parseByteMode(dataBlocks, version)
+}
+
+// functionModules independently reconstructs which modules belong to
+// finder, timing, alignment, dark, and format/version-info patterns, so
+// the decoder's notion of "this is a function module" does not come
+// from the same code path that placed the data.
+func functionModules(size, version int, alignment []int) []bool {
+	reserved := make([]bool, size*size)
+	mark := func(x, y int) {
+		if x >= 0 && y >= 0 && x < size && y < size {
+			reserved[y*size+x] = true
+		}
+	}
+	markFinder := func(x, y int) {
+		for dy := -1; dy <= 7; dy++ {
+			for dx := -1; dx <= 7; dx++ {
+				mark(x+dx, y+dy)
+			}
+		}
+	}
+	markFinder(0, 0)
+	markFinder(size-7, 0)
+	markFinder(0, size-7)
+
+	for i := 8; i < size-8; i++ {
+		mark(i, 6)
+		mark(6, i)
+	}
+
+	overlapsFinder := func(cx, cy int) bool {
+		return (cx <= 8 && cy <= 8) || (cx >= size-9 && cy <= 8) || (cx <= 8 && cy >= size-9)
+	}
+	for _, r := range alignment {
+		for _, c := range alignment {
+			if overlapsFinder(c, r) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					mark(c+dx, r+dy)
+				}
+			}
+		}
+	}
+
+	mark(8, 4*version+9) // dark module
+
+	for i := 0; i <= 5; i++ {
+		mark(i, 8)
+	}
+	mark(7, 8)
+	mark(8, 8)
+	mark(8, 7)
+	for row := 0; row <= 5; row++ {
+		mark(8, row)
+	}
+	for i := 0; i <= 7; i++ {
+		mark(size-1-i, 8)
+	}
+	for row := size - 7; row < size; row++ {
+		mark(8, row)
+	}
+
+	if version >= 7 {
+		for col := 0; col < 6; col++ {
+			for row := size - 11; row < size-8; row++ {
+				mark(col, row)
+				mark(row, col)
+			}
+		}
+	}
+
+	return reserved
+}
+
+// readMask recovers the mask index from the format-info strip around
+// the top-left finder, independently of matrix.placeFormatInfo.
+func readMask(code *Code) (int, error) {
+	get := func(x, y int) bool { return code.At(x, y) }
+
+	var raw uint32
+	setBit := func(i int, v bool) {
+		if v {
+			raw |= 1 << uint(14-i)
+		}
+	}
+
+	for i := 0; i <= 5; i++ {
+		setBit(i, get(i, 8))
+	}
+	setBit(6, get(7, 8))
+	setBit(7, get(8, 8))
+	setBit(8, get(8, 7))
+	idx := 9
+	for row := 5; row >= 0; row-- {
+		setBit(idx, get(8, row))
+		idx++
+	}
+
+	data := (raw ^ 0x5412) >> 10
+	return int(data & 0x7), nil
+}
+
+// extractDataBits walks the same bottom-up/top-down, two-column zigzag
+// Encode uses, unmasking each non-function module as it goes.
+func extractDataBits(code *Code, reserved []bool, maskIndex int) []bool {
+	size := code.Size
+	f := maskFuncs[maskIndex]
+
+	var bits []bool
+	upward := true
+	for col := size - 1; col > 0; col -= 2 {
+		if col == 6 {
+			col--
+		}
+		for i := 0; i < size; i++ {
+			row := i
+			if upward {
+				row = size - 1 - i
+			}
+			for _, c := range [2]int{col, col - 1} {
+				if reserved[row*size+c] {
+					continue
+				}
+				v := code.At(c, row)
+				if f(c, row) {
+					v = !v
+				}
+				bits = append(bits, v)
+			}
+		}
+		upward = !upward
+	}
+	return bits
+}
+
+func bitsToBytes(bits []bool) []byte {
+	out := make([]byte, len(bits)/8)
+	for i := range out {
+		var b byte
+		for j := 0; j < 8; j++ {
+			b <<= 1
+			if bits[i*8+j] {
+				b |= 1
+			}
+		}
+		out[i] = b
+	}
+	return out
+}
+
+// deinterleave is the inverse of the interleave function Encode uses: it
+// splits codewords back into per-block data and EC codewords following
+// the same group layout.
+func deinterleave(codewords []byte, vi versionInfo) (dataBlocks, ecBlocks [][]byte) {
+	var blockSizes []int
+	for i := 0; i < vi.group1Blocks; i++ {
+		blockSizes = append(blockSizes, vi.group1Size)
+	}
+	for i := 0; i < vi.group2Blocks; i++ {
+		blockSizes = append(blockSizes, vi.group2Size)
+	}
+
+	dataBlocks = make([][]byte, len(blockSizes))
+	maxSize := 0
+	for i, s := range blockSizes {
+		dataBlocks[i] = make([]byte, 0, s)
+		maxSize = max(maxSize, s)
+	}
+
+	pos := 0
+	for i := 0; i < maxSize; i++ {
+		for b, s := range blockSizes {
+			if i < s {
+				dataBlocks[b] = append(dataBlocks[b], codewords[pos])
+				pos++
+			}
+		}
+	}
+
+	ecBlocks = make([][]byte, len(blockSizes))
+	for i := range ecBlocks {
+		ecBlocks[i] = make([]byte, 0, vi.ecPerBlock)
+	}
+	for i := 0; i < vi.ecPerBlock; i++ {
+		for b := range blockSizes {
+			ecBlocks[b] = append(ecBlocks[b], codewords[pos])
+			pos++
+		}
+	}
+
+	return dataBlocks, ecBlocks
+}
+
+// parseByteMode reads the mode indicator, character count, and data
+// bytes back out of the concatenated data codewords.
+func parseByteMode(dataBlocks [][]byte, version int) (string, error) {
+	var all []byte
+	for _, b := range dataBlocks {
+		all = append(all, b...)
+	}
+
+	bits := make([]bool, len(all)*8)
+	for i, b := range all {
+		for j := 0; j < 8; j++ {
+			bits[i*8+j] = (b>>uint(7-j))&1 == 1
+		}
+	}
+
+	pos := 0
+	readBits := func(n int) uint32 {
+		var v uint32
+		for i := 0; i < n; i++ {
+			v <<= 1
+			if bits[pos+i] {
+				v |= 1
+			}
+		}
+		pos += n
+		return v
+	}
+
+	mode := readBits(4)
+	if mode != 0b0100 {
+		return "", fmt.Errorf("unexpected mode indicator %#04b", mode)
+	}
+
+	charCountBits := 8
+	if version >= 10 {
+		charCountBits = 16
+	}
+	count := readBits(charCountBits)
+
+	data := make([]byte, count)
+	for i := range data {
+		data[i] = byte(readBits(8))
+	}
+
+	return string(data), nil
+}
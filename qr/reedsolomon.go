@@ -0,0 +1,63 @@
+package qr
+
+// gfExp and gfLog are exponent/logarithm tables for GF(256) under the
+// primitive polynomial x^8+x^4+x^3+x^2+1 (0x11D), the field the QR code
+// standard uses for its Reed-Solomon error correction.
+var gfExp [512]byte
+var gfLog [256]byte
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	for i := 255; i < 512; i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// generatorPolynomial returns the Reed-Solomon generator polynomial for
+// degree EC codewords, as coefficients from highest to lowest degree.
+func generatorPolynomial(degree int) []byte {
+	g := []byte{1}
+	for i := 0; i < degree; i++ {
+		g = append(g, 0)
+		for j := len(g) - 1; j > 0; j-- {
+			g[j] ^= gfMul(g[j-1], gfExp[i])
+		}
+	}
+	return g
+}
+
+// reedSolomonEncode returns the ecCount error-correction codewords for a
+// single data block.
+func reedSolomonEncode(data []byte, ecCount int) []byte {
+	gen := generatorPolynomial(ecCount)
+
+	remainder := make([]byte, len(data)+ecCount)
+	copy(remainder, data)
+
+	for i := 0; i < len(data); i++ {
+		coef := remainder[i]
+		if coef == 0 {
+			continue
+		}
+		for j, g := range gen {
+			remainder[i+j] ^= gfMul(g, coef)
+		}
+	}
+
+	return remainder[len(data):]
+}
@@ -0,0 +1,131 @@
+package otp
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/MateoCaicedoW/otp/qr"
+)
+
+// qrQuietZone is the number of blank modules added around the symbol, as
+// the QR standard requires for reliable scanning.
+const qrQuietZone = 4
+
+// QRCode renders a QR code for c's otpauth:// URL, generated entirely
+// in-process, as an image.Image roughly size x size pixels. Unlike
+// QRCodeURL and its siblings, the otpauth URL (and the secret it embeds)
+// is never sent to a third party.
+func (c *Config) QRCode(size int) (image.Image, error) {
+	code, err := c.encodeQR()
+	if err != nil {
+		return nil, err
+	}
+
+	if size <= 0 {
+		size = 200
+	}
+	dim := code.Size + 2*qrQuietZone
+	scale := size / dim
+	if scale < 1 {
+		scale = 1
+	}
+	imgSize := scale * dim
+
+	img := image.NewGray(image.Rect(0, 0, imgSize, imgSize))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if !code.At(x, y) {
+				continue
+			}
+			px0, py0 := (x+qrQuietZone)*scale, (y+qrQuietZone)*scale
+			for py := py0; py < py0+scale; py++ {
+				for px := px0; px < px0+scale; px++ {
+					img.SetGray(px, py, color.Gray{})
+				}
+			}
+		}
+	}
+
+	return img, nil
+}
+
+// QRCodePNG renders c's QR code as a PNG image.
+func (c *Config) QRCodePNG(size int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.WriteQRCodePNG(&buf, size); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteQRCodePNG writes c's QR code to w as a PNG image, for streaming
+// directly from an HTTP handler. The image is rendered in memory first;
+// the PNG encoding is written straight to w without an extra byte buffer.
+func (c *Config) WriteQRCodePNG(w io.Writer, size int) error {
+	img, err := c.QRCode(size)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// QRCodeSVG renders c's QR code as a minimal SVG document.
+func (c *Config) QRCodeSVG(size int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.WriteQRCodeSVG(&buf, size); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteQRCodeSVG writes c's QR code to w as a minimal SVG document.
+func (c *Config) WriteQRCodeSVG(w io.Writer, size int) error {
+	code, err := c.encodeQR()
+	if err != nil {
+		return err
+	}
+
+	if size <= 0 {
+		size = 200
+	}
+	dim := code.Size + 2*qrQuietZone
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">`, dim, dim, size, size)
+	b.WriteString(`<rect width="100%" height="100%" fill="#fff"/>`)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if !code.At(x, y) {
+				continue
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="1" height="1" fill="#000"/>`, x+qrQuietZone, y+qrQuietZone)
+		}
+	}
+	b.WriteString(`</svg>`)
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// encodeQR returns the QR encoding of c's otpauth:// URL.
+func (c *Config) encodeQR() (*qr.Code, error) {
+	otpauthURL, err := c.OTPAuthURL()
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := qr.Encode([]byte(otpauthURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	return code, nil
+}
@@ -31,8 +31,34 @@ func ValidateSecret(secret string) error {
 	return nil
 }
 
-// decodeSecret decodes the base32 secret
+// decodeSecret decodes the base32 secret. It normalizes c.Secret into a
+// scratch buffer it zeroes before returning, so that copy of the secret
+// doesn't linger in memory; the string handed to base32.DecodeString
+// can't be zeroed the same way since Go strings are immutable; that copy
+// stays in memory until the garbage collector reclaims it.
 func (c *Config) decodeSecret() ([]byte, error) {
-	secret := strings.ToUpper(strings.ReplaceAll(c.Secret, " ", ""))
-	return base32.StdEncoding.DecodeString(secret)
+	buf := []byte(c.Secret)
+	defer zeroBytes(buf)
+
+	n := 0
+	for _, b := range buf {
+		if b == ' ' {
+			continue
+		}
+		if b >= 'a' && b <= 'z' {
+			b -= 'a' - 'A'
+		}
+		buf[n] = b
+		n++
+	}
+
+	return base32.StdEncoding.DecodeString(string(buf[:n]))
+}
+
+// zeroBytes overwrites b with zeros, for scrubbing scratch buffers that
+// briefly held secret material.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
 }
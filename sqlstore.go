@@ -0,0 +1,145 @@
+package otp
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SQLStore is a Store backed by a database/sql connection. It expects a
+// schema along the lines of:
+//
+//	CREATE TABLE otp (
+//		subject      TEXT PRIMARY KEY,
+//		secret       TEXT NOT NULL,
+//		digits       INTEGER NOT NULL,
+//		algorithm    INTEGER NOT NULL,
+//		period       INTEGER NOT NULL,
+//		counter      INTEGER NOT NULL,
+//		issuer       TEXT,
+//		account_name TEXT
+//	);
+//
+//	CREATE TABLE used_counters (
+//		subject TEXT NOT NULL,
+//		counter INTEGER NOT NULL,
+//		used_at TIMESTAMP NOT NULL,
+//		PRIMARY KEY (subject, counter)
+//	);
+type SQLStore struct {
+	// RetentionWindow bounds how far back RecentCodes looks. Zero means
+	// no limit.
+	RetentionWindow time.Duration
+
+	db *sql.DB
+}
+
+// NewSQLStore creates a SQLStore backed by db. The caller remains
+// responsible for the lifetime of db.
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// Get returns the stored configuration for subject.
+func (s *SQLStore) Get(subject string) (*Config, error) {
+	row := s.db.QueryRow(
+		`SELECT secret, digits, algorithm, period, counter, issuer, account_name
+		 FROM otp WHERE subject = ?`, subject)
+
+	c := &Config{}
+	var algorithm int
+	err := row.Scan(&c.Secret, &c.Digits, &algorithm, &c.Period, &c.Counter, &c.Issuer, &c.AccountName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSubjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config for subject %q: %w", subject, err)
+	}
+
+	c.Algorithm = Algorithm(algorithm)
+	return c, nil
+}
+
+// Save persists the configuration for subject, inserting or updating the
+// existing row.
+func (s *SQLStore) Save(subject string, c *Config) error {
+	_, err := s.db.Exec(
+		`INSERT INTO otp (subject, secret, digits, algorithm, period, counter, issuer, account_name)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(subject) DO UPDATE SET
+			secret = excluded.secret,
+			digits = excluded.digits,
+			algorithm = excluded.algorithm,
+			period = excluded.period,
+			counter = excluded.counter,
+			issuer = excluded.issuer,
+			account_name = excluded.account_name`,
+		subject, c.Secret, c.Digits, int(c.Algorithm), c.Period, c.Counter, c.Issuer, c.AccountName)
+	if err != nil {
+		return fmt.Errorf("failed to save config for subject %q: %w", subject, err)
+	}
+	return nil
+}
+
+// RecentCodes returns the counters recorded for subject within
+// RetentionWindow, most recent first.
+func (s *SQLStore) RecentCodes(subject string) ([]int, error) {
+	query := `SELECT counter FROM used_counters WHERE subject = ?`
+	args := []any{subject}
+	if s.RetentionWindow > 0 {
+		query += ` AND used_at >= ?`
+		args = append(args, time.Now().Add(-s.RetentionWindow))
+	}
+	query += ` ORDER BY used_at DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent codes for subject %q: %w", subject, err)
+	}
+	defer rows.Close()
+
+	var codes []int
+	for rows.Next() {
+		var counter int
+		if err := rows.Scan(&counter); err != nil {
+			return nil, fmt.Errorf("failed to scan recent code for subject %q: %w", subject, err)
+		}
+		codes = append(codes, counter)
+	}
+	return codes, rows.Err()
+}
+
+// RecordCode marks counter as used by subject.
+func (s *SQLStore) RecordCode(subject string, counter uint64) error {
+	_, err := s.db.Exec(
+		`INSERT INTO used_counters (subject, counter, used_at) VALUES (?, ?, ?)`,
+		subject, counter, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record used counter for subject %q: %w", subject, err)
+	}
+	return nil
+}
+
+// ConsumeCode atomically checks whether counter has already been
+// recorded for subject and, if not, records it. The used_counters
+// primary key (subject, counter) combined with ON CONFLICT DO NOTHING
+// makes this a single atomic statement: of two concurrent inserts for
+// the same pair, exactly one affects a row.
+func (s *SQLStore) ConsumeCode(subject string, counter uint64) (bool, error) {
+	result, err := s.db.Exec(
+		`INSERT INTO used_counters (subject, counter, used_at)
+		 VALUES (?, ?, ?)
+		 ON CONFLICT(subject, counter) DO NOTHING`,
+		subject, counter, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to record used counter for subject %q: %w", subject, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check rows affected for subject %q: %w", subject, err)
+	}
+
+	return affected == 1, nil
+}
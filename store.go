@@ -0,0 +1,130 @@
+package otp
+
+import (
+	"sync"
+	"time"
+)
+
+// Store persists OTP configurations and the counters that have already
+// been consumed for a subject, so a Validator can reject replayed codes
+// even across process restarts.
+type Store interface {
+	// Get returns the stored configuration for subject. It returns
+	// ErrSubjectNotFound if no configuration has been saved.
+	Get(subject string) (*Config, error)
+	// Save persists the configuration for subject, overwriting any
+	// existing configuration.
+	Save(subject string, c *Config) error
+	// RecentCodes returns the counters recorded for subject that are
+	// still within the store's retention window.
+	RecentCodes(subject string) ([]int, error)
+	// RecordCode marks counter as used by subject.
+	RecordCode(subject string, counter uint64) error
+	// ConsumeCode atomically checks whether counter has already been
+	// recorded as used for subject and, if not, records it in the same
+	// operation. It reports accepted=true only for the call that records
+	// it. Implementations must make the check-and-record atomic:
+	// concurrent callers presented with the same code must not both see
+	// accepted=true. Validator uses this, rather than RecentCodes plus
+	// RecordCode, to close that race.
+	ConsumeCode(subject string, counter uint64) (accepted bool, err error)
+}
+
+type usedCounter struct {
+	counter uint64
+	usedAt  time.Time
+}
+
+// MemoryStore is an in-memory Store, useful for tests and single-process
+// deployments. The zero value is not usable; construct one with
+// NewMemoryStore.
+type MemoryStore struct {
+	// RetentionWindow bounds how far back RecentCodes looks. Zero means
+	// no limit: every counter ever recorded for a subject is considered
+	// recent.
+	RetentionWindow time.Duration
+
+	mu      sync.Mutex
+	configs map[string]*Config
+	used    map[string][]usedCounter
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		configs: make(map[string]*Config),
+		used:    make(map[string][]usedCounter),
+	}
+}
+
+// Get returns the stored configuration for subject.
+func (s *MemoryStore) Get(subject string) (*Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.configs[subject]
+	if !ok {
+		return nil, ErrSubjectNotFound
+	}
+
+	cp := *c
+	return &cp, nil
+}
+
+// Save persists the configuration for subject.
+func (s *MemoryStore) Save(subject string, c *Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp := *c
+	s.configs[subject] = &cp
+	return nil
+}
+
+// RecentCodes returns the counters recorded for subject within
+// RetentionWindow.
+func (s *MemoryStore) RecentCodes(subject string) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var codes []int
+	cutoff := time.Now().Add(-s.RetentionWindow)
+	for _, u := range s.used[subject] {
+		if s.RetentionWindow > 0 && u.usedAt.Before(cutoff) {
+			continue
+		}
+		codes = append(codes, int(u.counter))
+	}
+	return codes, nil
+}
+
+// RecordCode marks counter as used by subject.
+func (s *MemoryStore) RecordCode(subject string, counter uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.used[subject] = append(s.used[subject], usedCounter{counter: counter, usedAt: time.Now()})
+	return nil
+}
+
+// ConsumeCode atomically checks whether counter has already been
+// recorded for subject and, if not, records it, all while holding mu —
+// so two concurrent calls for the same subject and counter can never
+// both see accepted=true.
+func (s *MemoryStore) ConsumeCode(subject string, counter uint64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-s.RetentionWindow)
+	for _, u := range s.used[subject] {
+		if s.RetentionWindow > 0 && u.usedAt.Before(cutoff) {
+			continue
+		}
+		if u.counter == counter {
+			return false, nil
+		}
+	}
+
+	s.used[subject] = append(s.used[subject], usedCounter{counter: counter, usedAt: time.Now()})
+	return true, nil
+}
@@ -0,0 +1,63 @@
+package otp
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestMemoryStoreConsumeCodeConcurrent guards against the check-then-act
+// race ConsumeCode was added to close: many goroutines racing to consume
+// the same subject/counter pair must produce exactly one accepted call.
+func TestMemoryStoreConsumeCodeConcurrent(t *testing.T) {
+	store := NewMemoryStore()
+
+	const goroutines = 20
+	var wg sync.WaitGroup
+	results := make([]bool, goroutines)
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			accepted, err := store.ConsumeCode("alice", 42)
+			if err != nil {
+				t.Errorf("ConsumeCode: %v", err)
+				return
+			}
+			results[i] = accepted
+		}(i)
+	}
+	wg.Wait()
+
+	accepted := 0
+	for _, ok := range results {
+		if ok {
+			accepted++
+		}
+	}
+	if accepted != 1 {
+		t.Fatalf("got %d accepted calls, want exactly 1", accepted)
+	}
+}
+
+func TestMemoryStoreConsumeCodeDistinctCounters(t *testing.T) {
+	store := NewMemoryStore()
+
+	for _, counter := range []uint64{1, 2, 3} {
+		accepted, err := store.ConsumeCode("alice", counter)
+		if err != nil {
+			t.Fatalf("ConsumeCode(%d): %v", counter, err)
+		}
+		if !accepted {
+			t.Fatalf("ConsumeCode(%d) = false, want true", counter)
+		}
+	}
+
+	accepted, err := store.ConsumeCode("alice", 2)
+	if err != nil {
+		t.Fatalf("ConsumeCode(2) again: %v", err)
+	}
+	if accepted {
+		t.Fatalf("ConsumeCode(2) a second time = true, want false")
+	}
+}
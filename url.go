@@ -8,6 +8,10 @@ import (
 )
 
 // QRCodeURL generates a QR code URL for authenticator apps using qr-server.com
+//
+// Deprecated: this sends the otpauth URL, including the shared secret, to
+// a third-party service. Use Config.QRCode, Config.QRCodePNG, or
+// Config.QRCodeSVG to render the code locally instead.
 func (c *Config) QRCodeURL() (string, error) {
 	if c.Secret == "" {
 		return "", ErrMissingSecret
@@ -31,6 +35,10 @@ func (c *Config) QRCodeURL() (string, error) {
 }
 
 // QRCodeURLWithCustomSize generates a QR code URL with custom size
+//
+// Deprecated: this sends the otpauth URL, including the shared secret, to
+// a third-party service. Use Config.QRCode, Config.QRCodePNG, or
+// Config.QRCodeSVG to render the code locally instead.
 func (c *Config) QRCodeURLWithCustomSize(size int) (string, error) {
 	if size <= 0 {
 		size = 200 // Default size
@@ -186,6 +194,10 @@ func ParseOTPAuthURL(otpauthURL string) (*Config, error) {
 }
 
 // QRCodeURLQuickChart generates a QR code URL using QuickChart.io
+//
+// Deprecated: this sends the otpauth URL, including the shared secret, to
+// a third-party service. Use Config.QRCode, Config.QRCodePNG, or
+// Config.QRCodeSVG to render the code locally instead.
 func (c *Config) QRCodeURLQuickChart() (string, error) {
 	if c.Secret == "" {
 		return "", ErrMissingSecret
@@ -209,6 +221,10 @@ func (c *Config) QRCodeURLQuickChart() (string, error) {
 }
 
 // QRCodeURLGoQR generates a QR code URL using goQR.me
+//
+// Deprecated: this sends the otpauth URL, including the shared secret, to
+// a third-party service. Use Config.QRCode, Config.QRCodePNG, or
+// Config.QRCodeSVG to render the code locally instead.
 func (c *Config) QRCodeURLGoQR() (string, error) {
 	if c.Secret == "" {
 		return "", ErrMissingSecret
@@ -0,0 +1,111 @@
+package otp
+
+import "time"
+
+// Validator adds replay protection on top of Config.ValidateTOTP and
+// Config.ValidateHOTP: a code whose matched counter has already been
+// recorded for the subject is rejected instead of accepted a second
+// time, every successful match is persisted, and a successful HOTP
+// match advances the subject's counter past the one that matched so
+// look-ahead resync sticks across calls.
+type Validator struct {
+	Store Store
+}
+
+// NewValidator creates a Validator backed by store.
+func NewValidator(store Store) *Validator {
+	return &Validator{Store: store}
+}
+
+// ValidateTOTP validates code for subject against the subject's stored
+// configuration at the current time.
+func (v *Validator) ValidateTOTP(subject, code string, windowSize int) (bool, error) {
+	return v.ValidateTOTPAt(subject, code, time.Now(), windowSize)
+}
+
+// ValidateTOTPAt validates code for subject against the subject's stored
+// configuration at time t.
+func (v *Validator) ValidateTOTPAt(subject, code string, t time.Time, windowSize int) (bool, error) {
+	c, err := v.Store.Get(subject)
+	if err != nil {
+		return false, err
+	}
+
+	if windowSize < 0 {
+		windowSize = 1
+	}
+	if c.Period <= 0 {
+		c.Period = 30
+	}
+
+	currentCounter := uint64(t.Unix()) / uint64(c.Period)
+
+	for i := -windowSize; i <= windowSize; i++ {
+		counter := currentCounter + uint64(i)
+		expectedCode, err := c.generateOTP(counter)
+		if err != nil {
+			return false, err
+		}
+		if code != expectedCode {
+			continue
+		}
+
+		return v.acceptMatch(subject, c, counter, false)
+	}
+
+	return false, nil
+}
+
+// ValidateHOTP validates code for subject against the subject's stored
+// configuration, starting from its current counter. On a successful
+// match, it advances and saves Config.Counter to matched+1.
+func (v *Validator) ValidateHOTP(subject, code string, windowSize int) (bool, uint64, error) {
+	c, err := v.Store.Get(subject)
+	if err != nil {
+		return false, 0, err
+	}
+
+	if windowSize < 0 {
+		windowSize = 10
+	}
+
+	for i := 0; i <= windowSize; i++ {
+		counter := c.Counter + uint64(i)
+		expectedCode, err := c.generateOTP(counter)
+		if err != nil {
+			return false, 0, err
+		}
+		if code != expectedCode {
+			continue
+		}
+
+		ok, err := v.acceptMatch(subject, c, counter, true)
+		return ok, counter, err
+	}
+
+	return false, 0, nil
+}
+
+// acceptMatch atomically rejects counter if it was already recorded for
+// subject, otherwise records it and, for HOTP, resyncs and saves the
+// subject's counter. The check-and-record step goes through
+// Store.ConsumeCode rather than a separate RecentCodes-then-RecordCode
+// pair, so two concurrent calls matching the same code can't both win.
+func (v *Validator) acceptMatch(subject string, c *Config, counter uint64, resyncHOTP bool) (bool, error) {
+	accepted, err := v.Store.ConsumeCode(subject, counter)
+	if err != nil {
+		return false, err
+	}
+	if !accepted {
+		return false, ErrCodeAlreadyUsed
+	}
+
+	if resyncHOTP {
+		c.Counter = counter + 1
+		if err := v.Store.Save(subject, c); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}